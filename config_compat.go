@@ -0,0 +1,169 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+import (
+	"strings"
+	"sync"
+)
+
+// configKeyRenames maps a config key as spelled before liblxc 2.1's
+// config rewrite to the name it carries on liblxc >= 2.1. LXD has long
+// carried an out-of-tree copy of this table; it lives here so callers
+// don't each have to reinvent it.
+var configKeyRenames = map[string]string{
+	"lxc.utsname":        "lxc.uts.name",
+	"lxc.pts":            "lxc.pty.max",
+	"lxc.tty":            "lxc.tty.max",
+	"lxc.mount":          "lxc.mount.fstab",
+	"lxc.hook.pre_start": "lxc.hook.pre-start",
+}
+
+var configKeyRenamesReverse = reverseConfigKeyMap(configKeyRenames)
+
+func reverseConfigKeyMap(m map[string]string) map[string]string {
+	r := make(map[string]string, len(m))
+	for k, v := range m {
+		r[v] = k
+	}
+	return r
+}
+
+// lxc.cgroup.* and lxc.cgroup2.* aren't a fixed set of renamed keys but a
+// whole-prefix rewrite, so they're handled separately from
+// configKeyRenames rather than being enumerated into it.
+const (
+	cgroupOldPrefix = "lxc.cgroup."
+	cgroupNewPrefix = "lxc.cgroup2."
+)
+
+// NormalizeConfigKey rewrites key into the spelling expected by the
+// linked liblxc, translating between the pre-2.1 and post-2.1 config key
+// names as needed (e.g. lxc.utsname <-> lxc.uts.name, lxc.cgroup.* <->
+// lxc.cgroup2.*). Keys that don't appear in the translation table, such
+// as lxc.mount.entry, are returned unchanged.
+func NormalizeConfigKey(key string) string {
+	atLeast21 := RuntimeLiblxcVersionAtLeast(Version(), 2, 1, 0)
+
+	switch {
+	case strings.HasPrefix(key, cgroupOldPrefix):
+		if atLeast21 {
+			return cgroupNewPrefix + strings.TrimPrefix(key, cgroupOldPrefix)
+		}
+		return key
+	case strings.HasPrefix(key, cgroupNewPrefix):
+		if !atLeast21 {
+			return cgroupOldPrefix + strings.TrimPrefix(key, cgroupNewPrefix)
+		}
+		return key
+	}
+
+	if atLeast21 {
+		if renamed, ok := configKeyRenames[key]; ok {
+			return renamed
+		}
+		return key
+	}
+
+	if renamed, ok := configKeyRenamesReverse[key]; ok {
+		return renamed
+	}
+	return key
+}
+
+// ConfigKeyTranslations returns a copy of the built-in old-to-new config
+// key translation table, for callers that want to introspect it. The
+// lxc.cgroup.* <-> lxc.cgroup2.* prefix rewrite isn't included since it
+// applies to every key under those prefixes rather than a fixed set of
+// names; see NormalizeConfigKey.
+func ConfigKeyTranslations() map[string]string {
+	out := make(map[string]string, len(configKeyRenames))
+	for k, v := range configKeyRenames {
+		out[k] = v
+	}
+	return out
+}
+
+// configKeyCompatKey identifies a container for the purposes of the
+// compat toggle below. Keying on (name, lxcpath) rather than on the
+// *Container pointer itself, the same identity the registry in
+// registry.go uses, means the toggle doesn't keep an arbitrary number of
+// past *Container values reachable forever: re-opening the same
+// container only ever occupies one entry, and the entry doesn't pin any
+// particular Container object alive.
+type configKeyCompatKey struct {
+	name    string
+	lxcpath string
+}
+
+func configKeyCompatKeyFor(c *Container) configKeyCompatKey {
+	return configKeyCompatKey{name: c.Name(), lxcpath: c.ConfigPath()}
+}
+
+var (
+	configKeyCompatMu      sync.RWMutex
+	configKeyCompatEnabled = make(map[configKeyCompatKey]bool)
+)
+
+// SetConfigKeyCompat toggles automatic config key translation for c. When
+// enabled, SetConfigItemCompat, GetConfigItemCompat and
+// AppendConfigItemCompat rewrite keys via NormalizeConfigKey before
+// touching the real config, so callers can use either the pre- or
+// post-2.1 key spelling regardless of the linked liblxc version. The
+// default is disabled, matching the long-standing SetConfigItem behavior.
+func (c *Container) SetConfigKeyCompat(enabled bool) {
+	key := configKeyCompatKeyFor(c)
+
+	configKeyCompatMu.Lock()
+	defer configKeyCompatMu.Unlock()
+
+	if enabled {
+		configKeyCompatEnabled[key] = true
+		return
+	}
+	delete(configKeyCompatEnabled, key)
+}
+
+// ConfigKeyCompat reports whether c has opted into automatic config key
+// translation.
+func (c *Container) ConfigKeyCompat() bool {
+	configKeyCompatMu.RLock()
+	defer configKeyCompatMu.RUnlock()
+
+	return configKeyCompatEnabled[configKeyCompatKeyFor(c)]
+}
+
+// SetConfigItemCompat behaves like SetConfigItem but, when compat mode is
+// enabled for c via SetConfigKeyCompat, first rewrites key with
+// NormalizeConfigKey so either key spelling works regardless of the
+// linked liblxc version.
+func (c *Container) SetConfigItemCompat(key string, value string) error {
+	if c.ConfigKeyCompat() {
+		key = NormalizeConfigKey(key)
+	}
+	return c.SetConfigItem(key, value)
+}
+
+// GetConfigItemCompat behaves like GetConfigItem but, when compat mode is
+// enabled for c via SetConfigKeyCompat, first rewrites key with
+// NormalizeConfigKey.
+func (c *Container) GetConfigItemCompat(key string) []string {
+	if c.ConfigKeyCompat() {
+		key = NormalizeConfigKey(key)
+	}
+	return c.GetConfigItem(key)
+}
+
+// AppendConfigItemCompat behaves like AppendConfigItem but, when compat
+// mode is enabled for c via SetConfigKeyCompat, first rewrites key with
+// NormalizeConfigKey.
+func (c *Container) AppendConfigItemCompat(key string, value string) error {
+	if c.ConfigKeyCompat() {
+		key = NormalizeConfigKey(key)
+	}
+	return c.AppendConfigItem(key, value)
+}
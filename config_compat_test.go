@@ -0,0 +1,62 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+import "testing"
+
+// TestNormalizeConfigKeyIsIdempotent holds regardless of the linked
+// liblxc version: NormalizeConfigKey always rewrites into whatever
+// spelling that version expects, so normalizing an already-normalized key
+// must be a no-op.
+func TestNormalizeConfigKeyIsIdempotent(t *testing.T) {
+	for key := range configKeyRenames {
+		normalized := NormalizeConfigKey(key)
+		if again := NormalizeConfigKey(normalized); again != normalized {
+			t.Errorf("NormalizeConfigKey(%q) = %q, but normalizing that again gave %q", key, normalized, again)
+		}
+	}
+}
+
+func TestConfigKeyTranslationsIsACopy(t *testing.T) {
+	out := ConfigKeyTranslations()
+	out["lxc.utsname"] = "mutated"
+
+	if configKeyRenames["lxc.utsname"] == "mutated" {
+		t.Fatalf("ConfigKeyTranslations leaked a mutable reference to the internal table")
+	}
+}
+
+// TestConfigKeyCompatSharedByIdentity ensures the compat toggle follows a
+// container's (name, lxcpath) identity rather than a specific *Container
+// pointer: re-opening the same container by name must observe a toggle
+// made through an earlier, separately obtained *Container value.
+func TestConfigKeyCompatSharedByIdentity(t *testing.T) {
+	const name = "go-lxc-config-compat-test"
+
+	c1, err := NewContainer(name)
+	if err != nil {
+		t.Fatalf("NewContainer: %v", err)
+	}
+	defer c1.Release()
+
+	c1.SetConfigKeyCompat(true)
+
+	c2, err := NewContainer(name)
+	if err != nil {
+		t.Fatalf("NewContainer: %v", err)
+	}
+	defer c2.Release()
+
+	if !c2.ConfigKeyCompat() {
+		t.Fatalf("expected a second *Container for %q to observe the compat toggle set through the first", name)
+	}
+
+	c1.SetConfigKeyCompat(false)
+	if c2.ConfigKeyCompat() {
+		t.Fatalf("expected disabling compat through c1 to be visible through c2")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+import (
+	"context"
+	"syscall"
+	"time"
+)
+
+// runContext runs fn on a dedicated goroutine and returns its result, or
+// ctx.Err() if ctx is done first. fn is not interrupted when ctx wins the
+// race; it keeps running in the background and its eventual result is
+// discarded. FreezeContext, UnfreezeContext, CreateContext and
+// CloneContext are built on it; the other *Context methods in this file
+// run their own select so they can act on cancellation.
+func runContext(ctx context.Context, fn func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// StartContext behaves like Start but returns ctx.Err() as soon as ctx is
+// done, without waiting for Start. If that Start later succeeds, the
+// container is stopped again in the background. liblxc has no way to
+// interrupt Start mid-flight, so cancellation doesn't change how quickly
+// Start itself exits.
+func (c *Container) StartContext(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.Start()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		go func() {
+			if err := <-errCh; err == nil {
+				c.Stop()
+			}
+		}()
+		return ctx.Err()
+	}
+}
+
+// StopContext behaves like Stop but escalates to a SIGKILL of the
+// container's init process if ctx is done before the clean stop
+// completes.
+func (c *Container) StopContext(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.Stop()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		if pid, err := c.InitPid(); err == nil && pid > 0 {
+			syscall.Kill(pid, syscall.SIGKILL)
+		}
+		return ctx.Err()
+	}
+}
+
+// ShutdownContext behaves like Shutdown but escalates to StopContext
+// (and, in turn, a SIGKILL of the init process) if ctx is done before
+// timeout elapses.
+func (c *Container) ShutdownContext(ctx context.Context, timeout int) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.Shutdown(timeout)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return c.StopContext(ctx)
+	}
+}
+
+// WaitContext behaves like Wait but polls State() at short intervals
+// instead of blocking inside liblxc's own timeout handling, so it can
+// honor ctx cancellation.
+func (c *Container) WaitContext(ctx context.Context, state State) error {
+	const pollInterval = 250 * time.Millisecond
+
+	if c.State() == state {
+		return nil
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if c.State() == state {
+				return nil
+			}
+		}
+	}
+}
+
+// FreezeContext behaves like Freeze but returns ctx.Err() if ctx is done
+// before the freeze completes.
+func (c *Container) FreezeContext(ctx context.Context) error {
+	return runContext(ctx, c.Freeze)
+}
+
+// UnfreezeContext behaves like Unfreeze but returns ctx.Err() if ctx is
+// done before the unfreeze completes.
+func (c *Container) UnfreezeContext(ctx context.Context) error {
+	return runContext(ctx, c.Unfreeze)
+}
+
+// CreateContext behaves like Create but returns ctx.Err() if ctx is done
+// before the template finishes running. Template execution isn't
+// interruptible mid-flight, so cancellation changes how quickly the
+// caller gets control back, not whether a partially created container is
+// left behind; callers should Destroy it themselves if that matters.
+func (c *Container) CreateContext(ctx context.Context, options TemplateOptions) error {
+	return runContext(ctx, func() error {
+		return c.Create(options)
+	})
+}
+
+// CloneContext behaves like Clone but returns ctx.Err() if ctx is done
+// before the clone finishes.
+func (c *Container) CloneContext(ctx context.Context, name string, options CloneOptions) error {
+	return runContext(ctx, func() error {
+		return c.Clone(name, options)
+	})
+}
+
+// AttachShellContext behaves like AttachShell but kills the attached
+// process with SIGKILL if ctx is done before it exits.
+//
+// It's built on RunCommandNoWait rather than AttachShell directly because
+// RunCommandNoWait hands back the pid lxc_attach itself returns to the
+// host side at fork time, before the attached process has finished
+// running. That's the pid syscall.Kill needs. A pid self-reported by code
+// running inside the attached process (e.g. via os.Getpid) would be
+// relative to the container's own, usually unshared, PID namespace and
+// have no relation to the host pid, making a SIGKILL against it either a
+// no-op or, on a host where that number happens to be in use, a kill of
+// an unrelated process.
+func (c *Container) AttachShellContext(ctx context.Context, options AttachOptions) error {
+	pid, err := c.RunCommandNoWait(nil, options)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		var ws syscall.WaitStatus
+		_, err := syscall.Wait4(pid, &ws, 0, nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		syscall.Kill(pid, syscall.SIGKILL)
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRunContextReturnsCtxErrOnCancel covers the race at the heart of
+// the runContext-based *Context methods: runContext must return ctx.Err()
+// as soon as ctx is done, regardless of whether the underlying call has
+// finished, not wait around for it.
+func TestRunContextReturnsCtxErrOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	started := make(chan struct{})
+	unobserved := errors.New("runContext should not have waited for this")
+
+	err := runContext(ctx, func() error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return unobserved
+	})
+
+	<-started
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRunContextReturnsFnResultWhenNotCanceled(t *testing.T) {
+	want := errors.New("boom")
+
+	err := runContext(context.Background(), func() error {
+		return want
+	})
+	if err != want {
+		t.Fatalf("expected %v, got %v", want, err)
+	}
+}
+
+// TestWaitContextReturnsImmediatelyWhenAlreadyInState covers the
+// already-in-target-state fast path: it must return nil before ever
+// touching ctx, even if ctx is already canceled.
+func TestWaitContextReturnsImmediatelyWhenAlreadyInState(t *testing.T) {
+	c, err := NewContainer("go-lxc-context-test-wait-noop")
+	if err != nil {
+		t.Fatalf("NewContainer: %v", err)
+	}
+	defer c.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := c.WaitContext(ctx, c.State()); err != nil {
+		t.Fatalf("expected nil when already in the target state, got %v", err)
+	}
+}
+
+// TestWaitContextHonorsCancellation covers the polling loop: when the
+// target state is never reached, an already-done ctx must short-circuit
+// with ctx.Err() instead of polling forever.
+func TestWaitContextHonorsCancellation(t *testing.T) {
+	c, err := NewContainer("go-lxc-context-test-wait-cancel")
+	if err != nil {
+		t.Fatalf("NewContainer: %v", err)
+	}
+	defer c.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// -1 is not a real liblxc state, so the freshly created container can
+	// never already be in it.
+	if err := c.WaitContext(ctx, State(-1)); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
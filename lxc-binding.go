@@ -118,6 +118,9 @@ func ContainerNames(lxcpath ...string) []string {
 // Containers returns the defined and active containers on the system. Only
 // containers that could retrieved successfully are returned.
 // Caller needs to call Release() on the returned containers to release resources.
+// Deprecated: Use OpenContainers instead, which shares a single
+// reference-counted container object across concurrent openers instead of
+// requiring every caller to remember to call Release().
 func Containers(lxcpath ...string) []*Container {
 	var containers []*Container
 
@@ -154,6 +157,9 @@ func DefinedContainerNames(lxcpath ...string) []string {
 // DefinedContainers returns the defined containers on the system.  Only
 // containers that could retrieved successfully are returned.
 // Caller needs to call Release() on the returned containers to release resources.
+// Deprecated: Use OpenDefinedContainers instead, which shares a single
+// reference-counted container object across concurrent openers instead of
+// requiring every caller to remember to call Release().
 func DefinedContainers(lxcpath ...string) []*Container {
 	var containers []*Container
 
@@ -190,6 +196,9 @@ func ActiveContainerNames(lxcpath ...string) []string {
 // ActiveContainers returns the active containers on the system. Only
 // containers that could retrieved successfully are returned.
 // Caller needs to call Release() on the returned containers to release resources.
+// Deprecated: Use OpenActiveContainers instead, which shares a single
+// reference-counted container object across concurrent openers instead of
+// requiring every caller to remember to call Release().
 func ActiveContainers(lxcpath ...string) []*Container {
 	var containers []*Container
 
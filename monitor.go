@@ -0,0 +1,176 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+// #include <lxc/lxccontainer.h>
+// #include <lxc/monitor.h>
+import "C"
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"context"
+)
+
+// EventType identifies the kind of message carried by an Event.
+type EventType int
+
+const (
+	// EventStateChanged is emitted when a container transitions between
+	// lifecycle states, e.g. STOPPED -> RUNNING.
+	EventStateChanged EventType = iota
+	// EventExitCode is emitted once a container's init process has
+	// exited, carrying its exit code.
+	EventExitCode
+)
+
+// Event describes a single message read off liblxc's monitor socket.
+type Event struct {
+	Type      EventType
+	Container string
+	LxcPath   string
+	OldState  State
+	NewState  State
+	ExitCode  int
+	Time      time.Time
+}
+
+// monitorPollInterval bounds how long a single lxc_monitor_read_timeout
+// call blocks, so the reader goroutine can notice ctx.Done() promptly
+// instead of being stuck inside the cgo call.
+const monitorPollInterval = 1
+
+// Monitor opens liblxc's monitor socket for lxcpath and streams state
+// changes and exit codes as Events on the returned channel until ctx is
+// done, at which point the channel is closed. When filter is non-empty,
+// only events for the named containers are delivered.
+func Monitor(ctx context.Context, lxcpath string, filter ...string) (<-chan Event, error) {
+	clxcpath := C.CString(lxcpath)
+	defer C.free(unsafe.Pointer(clxcpath))
+
+	fd := C.lxc_monitor_open(clxcpath)
+	if fd < 0 {
+		return nil, fmt.Errorf("lxc: failed to open monitor on %q", lxcpath)
+	}
+
+	wanted := make(map[string]bool, len(filter))
+	for _, name := range filter {
+		wanted[name] = true
+	}
+
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+		defer C.lxc_monitor_close(fd)
+
+		// lastState tracks, per container name, the most recent state
+		// reported by this monitor socket, so every EventStateChanged can
+		// carry OldState alongside NewState without each caller having to
+		// track it themselves (WatchState used to do exactly this
+		// per-container bookkeeping itself; now it just reads it off Event).
+		lastState := make(map[string]State)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			var msg C.struct_lxc_msg
+			ret := C.lxc_monitor_read_timeout(fd, &msg, monitorPollInterval)
+			if ret < 0 {
+				return
+			}
+			if ret == 0 {
+				continue
+			}
+
+			name := C.GoString(&msg.name[0])
+			if len(wanted) > 0 && !wanted[name] {
+				continue
+			}
+
+			ev := Event{
+				Container: name,
+				LxcPath:   lxcpath,
+				Time:      time.Now(),
+			}
+
+			switch msg._type {
+			case C.lxc_msg_state:
+				ev.Type = EventStateChanged
+				ev.OldState = lastState[name]
+				ev.NewState = State(msg.value)
+				lastState[name] = ev.NewState
+			case C.lxc_msg_exit_code:
+				ev.Type = EventExitCode
+				ev.ExitCode = int(msg.value)
+			default:
+				continue
+			}
+
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// Watch multiplexes state-change and exit events for every container
+// under lxcpath, so higher-level tooling doesn't have to poll
+// ActiveContainerNames in a loop. It's a thin wrapper around Monitor with
+// no filter, so every container sharing the monitor socket is covered.
+func Watch(ctx context.Context, lxcpath string) (<-chan Event, error) {
+	return Monitor(ctx, lxcpath)
+}
+
+// StateChange describes a single state transition observed by WatchState.
+type StateChange struct {
+	From State
+	To   State
+	Time time.Time
+}
+
+// WatchState streams state transitions for c until ctx is done, at which
+// point the returned channel is closed. It's a convenience layer over
+// Monitor scoped to this container's config path and name.
+func (c *Container) WatchState(ctx context.Context) <-chan StateChange {
+	out := make(chan StateChange)
+
+	go func() {
+		defer close(out)
+
+		events, err := Monitor(ctx, c.ConfigPath(), c.Name())
+		if err != nil {
+			return
+		}
+
+		for ev := range events {
+			if ev.Type != EventStateChanged {
+				continue
+			}
+
+			change := StateChange{From: ev.OldState, To: ev.NewState, Time: ev.Time}
+
+			select {
+			case out <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
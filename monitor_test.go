@@ -0,0 +1,58 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestMonitorClosesPromptlyOnCancel covers the ctx-already-done check at
+// the top of Monitor's reader loop: it must close the channel itself
+// rather than block forever inside lxc_monitor_read_timeout, even if no
+// container ever reports an event.
+func TestMonitorClosesPromptlyOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	events, err := Monitor(ctx, DefaultConfigPath())
+	if err != nil {
+		t.Fatalf("Monitor: %v", err)
+	}
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatalf("expected no events to be delivered on an already-canceled context")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for Monitor to close its channel after ctx was already done")
+	}
+}
+
+// TestWatchIsMonitorWithNoFilter documents that Watch is a thin,
+// unfiltered wrapper around Monitor, so it inherits the same prompt
+// shutdown behavior on an already-canceled ctx.
+func TestWatchIsMonitorWithNoFilter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	events, err := Watch(ctx, DefaultConfigPath())
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatalf("expected no events to be delivered on an already-canceled context")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for Watch to close its channel after ctx was already done")
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrHandleAcquireFailed is returned by Open when a second handle on an
+// already-open container can't acquire a reference to it.
+var ErrHandleAcquireFailed = errors.New("lxc: failed to acquire container reference")
+
+// Handle is a reference-counted handle on a container object. Every
+// Handle opened on the same (name, lxcpath) pair shares a single
+// underlying container. Each Handle after the first takes its own
+// reference via lxc_container_get, and each Handle drops one via Release
+// when it closes, so the underlying container is freed only once the last
+// Handle on it has closed. Handle embeds
+// *Container, so it supports the full Container API; callers just need
+// to call Close instead of Release when they're done.
+type Handle struct {
+	*Container
+
+	key      entryKey
+	closeOne sync.Once
+}
+
+type entryKey struct {
+	name    string
+	lxcpath string
+}
+
+type registryEntry struct {
+	container *Container
+	refs      int
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[entryKey]*registryEntry)
+)
+
+// Open returns a Handle on the container identified by name and lxcpath,
+// creating the underlying container on first use and sharing it with any
+// other open Handle on the same (name, lxcpath) pair. Callers must call
+// Close on the returned Handle when done with it; a runtime.SetFinalizer
+// safety net closes leaked handles, but callers should not rely on it.
+func Open(name string, lxcpath ...string) (*Handle, error) {
+	var path string
+	if len(lxcpath) == 1 {
+		path = lxcpath[0]
+	}
+	key := entryKey{name: name, lxcpath: path}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	entry, ok := registry[key]
+	if !ok {
+		c, err := NewContainer(name, lxcpath...)
+		if err != nil {
+			return nil, err
+		}
+		entry = &registryEntry{container: c, refs: 1}
+		registry[key] = entry
+	} else {
+		if !Acquire(entry.container) {
+			return nil, ErrHandleAcquireFailed
+		}
+		entry.refs++
+	}
+
+	h := &Handle{Container: entry.container, key: key}
+	runtime.SetFinalizer(h, (*Handle).finalize)
+
+	return h, nil
+}
+
+// Close releases h's reference on the underlying container, releasing
+// the container itself only once the last Handle on it has closed. Close
+// is safe to call more than once: only the first call (from Close or the
+// finalizer, whichever runs first) actually releases a reference: h.key's
+// entry may still be shared with other live Handles, and re-entering the
+// refcount bookkeeping a second time for the same Handle would release a
+// reference h never held, freeing the underlying container out from
+// under whichever other Handle is still relying on it.
+func (h *Handle) Close() error {
+	runtime.SetFinalizer(h, nil)
+	return h.release()
+}
+
+func (h *Handle) finalize() {
+	h.release()
+}
+
+func (h *Handle) release() error {
+	var err error
+
+	h.closeOne.Do(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+
+		entry, ok := registry[h.key]
+		if !ok || entry.container != h.Container {
+			return
+		}
+
+		entry.refs--
+		if entry.refs <= 0 {
+			delete(registry, h.key)
+		}
+
+		// Every Open() on an already-open entry paired a lxc_container_get
+		// (via Acquire) with the container's creation-time refcount of 1;
+		// each Handle's release must issue its own lxc_container_put to
+		// match, not just the one that happens to observe refs hit 0.
+		// Otherwise the C-side refcount stays inflated by however many
+		// extra Acquire calls were made, with no Go reference left to ever
+		// release it. closeOne above ensures this runs at most once per
+		// Handle value, regardless of how many times Close or the
+		// finalizer fire for it.
+		err = entry.container.Release()
+	})
+
+	return err
+}
+
+// OpenContainers returns a Handle for every defined and active container
+// under lxcpath. Unlike Containers, callers only need to Close the
+// returned Handles, and opening the same container elsewhere shares
+// rather than duplicates it.
+func OpenContainers(lxcpath ...string) []*Handle {
+	return openAll(ContainerNames(lxcpath...), lxcpath...)
+}
+
+// OpenDefinedContainers returns a Handle for every defined container
+// under lxcpath. See OpenContainers.
+func OpenDefinedContainers(lxcpath ...string) []*Handle {
+	return openAll(DefinedContainerNames(lxcpath...), lxcpath...)
+}
+
+// OpenActiveContainers returns a Handle for every active container under
+// lxcpath. See OpenContainers.
+func OpenActiveContainers(lxcpath ...string) []*Handle {
+	return openAll(ActiveContainerNames(lxcpath...), lxcpath...)
+}
+
+func openAll(names []string, lxcpath ...string) []*Handle {
+	var handles []*Handle
+	for _, name := range names {
+		if h, err := Open(name, lxcpath...); err == nil {
+			handles = append(handles, h)
+		}
+	}
+	return handles
+}
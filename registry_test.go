@@ -0,0 +1,114 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+import "testing"
+
+// TestOpenSharesUnderlyingContainer checks that every Open() on the same
+// (name, lxcpath) shares one underlying container, that the registry
+// counts one ref per open Handle, that each Handle's Close() succeeds, and
+// that the registry entry is gone after the last Close(). It doesn't
+// observe the C-side refcount, so it can't tell whether each Close()
+// issued its own lxc_container_put.
+func TestOpenSharesUnderlyingContainer(t *testing.T) {
+	const name = "go-lxc-registry-test"
+
+	h1, err := Open(name)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	h2, err := Open(name)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	h3, err := Open(name)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if h1.Container != h2.Container || h2.Container != h3.Container {
+		t.Fatalf("expected every Open() on %q to share the same underlying container", name)
+	}
+
+	key := entryKey{name: name}
+	registryMu.Lock()
+	refs := registry[key].refs
+	registryMu.Unlock()
+	if refs != 3 {
+		t.Fatalf("expected 3 live refs after three Open() calls, got %d", refs)
+	}
+
+	for i, h := range []*Handle{h1, h2, h3} {
+		if err := h.Close(); err != nil {
+			t.Fatalf("Close() #%d: %v", i, err)
+		}
+	}
+
+	registryMu.Lock()
+	_, stillRegistered := registry[key]
+	registryMu.Unlock()
+	if stillRegistered {
+		t.Fatalf("expected registry entry for %q to be removed after the last Close()", name)
+	}
+}
+
+// TestHandleCloseIsIdempotent ensures a second Close() on an already
+// closed Handle is a no-op rather than releasing a reference that was
+// never acquired.
+func TestHandleCloseIsIdempotent(t *testing.T) {
+	h, err := Open("go-lxc-registry-test-idempotent")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("first Close(): %v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("second Close(): %v", err)
+	}
+}
+
+// TestHandleDoubleCloseDoesNotEvictSharedContainer guards against a
+// use-after-free: closing the same Handle value twice while a sibling
+// Handle on the same (name, lxcpath) is still alive must not release a
+// second C reference, or the sibling is left holding a container whose
+// underlying *C.struct_lxc_container has already been freed.
+func TestHandleDoubleCloseDoesNotEvictSharedContainer(t *testing.T) {
+	const name = "go-lxc-registry-test-double-close"
+
+	h1, err := Open(name)
+	if err != nil {
+		t.Fatalf("Open h1: %v", err)
+	}
+	h2, err := Open(name)
+	if err != nil {
+		t.Fatalf("Open h2: %v", err)
+	}
+	defer h2.Close()
+
+	if err := h1.Close(); err != nil {
+		t.Fatalf("first h1.Close(): %v", err)
+	}
+	if err := h1.Close(); err != nil {
+		t.Fatalf("second h1.Close(): %v", err)
+	}
+
+	key := entryKey{name: name}
+	registryMu.Lock()
+	entry, ok := registry[key]
+	registryMu.Unlock()
+	if !ok {
+		t.Fatalf("expected the registry entry for %q to still exist while h2 is open", name)
+	}
+	if entry.refs != 1 {
+		t.Fatalf("expected 1 remaining ref for h2, got %d", entry.refs)
+	}
+
+	// h2's underlying container must still be usable.
+	h2.State()
+}
@@ -0,0 +1,136 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+import "fmt"
+
+// knownConfigKeys is a curated, version-independent list of candidate keys
+// for SupportedConfigItems to probe. A key on it is reported only if
+// IsSupportedConfigItem confirms it for the linked liblxc.
+var knownConfigKeys = []string{
+	"lxc.arch",
+	"lxc.uts.name",
+	"lxc.include",
+	"lxc.rootfs.path",
+	"lxc.rootfs.mount",
+	"lxc.rootfs.options",
+	"lxc.mount.fstab",
+	"lxc.mount.entry",
+	"lxc.mount.auto",
+	"lxc.pty.max",
+	"lxc.tty.max",
+	"lxc.cgroup.",
+	"lxc.cgroup2.",
+	"lxc.hook.pre-start",
+	"lxc.hook.start",
+	"lxc.hook.stop",
+	"lxc.hook.post-stop",
+	"lxc.network.type",
+	"lxc.network.link",
+	"lxc.network.flags",
+	"lxc.start.auto",
+	"lxc.log.level",
+	"lxc.log.file",
+	"lxc.apparmor.profile",
+	"lxc.selinux.context",
+	"lxc.seccomp.profile",
+	"lxc.idmap",
+}
+
+// SupportedConfigItems enumerates every config key the linked liblxc
+// recognizes: the curated list above, filtered down to what
+// IsSupportedConfigItem actually confirms for this version, plus both
+// spellings of each key in the key compatibility table. Each spelling is
+// probed on its own, so the old or new spelling of a renamed key is left
+// out if IsSupportedConfigItem doesn't confirm that spelling.
+func SupportedConfigItems() []string {
+	seen := make(map[string]bool)
+	var supported []string
+
+	add := func(key string) {
+		if seen[key] || !IsSupportedConfigItem(key) {
+			return
+		}
+		seen[key] = true
+		supported = append(supported, key)
+	}
+
+	for _, key := range knownConfigKeys {
+		add(key)
+		add(NormalizeConfigKey(key))
+	}
+
+	for old, new := range configKeyRenames {
+		add(old)
+		add(new)
+	}
+
+	return supported
+}
+
+// ConfigErrorKind classifies why ValidateConfig flagged a key.
+type ConfigErrorKind int
+
+const (
+	// ConfigErrorUnknown means the linked liblxc doesn't recognize the
+	// key under any known spelling.
+	ConfigErrorUnknown ConfigErrorKind = iota
+	// ConfigErrorRenamed means the key is recognized by the running
+	// liblxc only under a different spelling.
+	ConfigErrorRenamed
+	// ConfigErrorDeprecated means the key works on the running liblxc but
+	// has been renamed in the key compatibility table; liblxc still
+	// accepts the old spelling via its own compat aliasing, but callers
+	// should move to Suggestion before it's eventually dropped.
+	ConfigErrorDeprecated
+)
+
+// ConfigError describes a single problem found by ValidateConfig.
+type ConfigError struct {
+	Key        string
+	Kind       ConfigErrorKind
+	Suggestion string
+}
+
+func (e ConfigError) Error() string {
+	switch e.Kind {
+	case ConfigErrorRenamed:
+		return fmt.Sprintf("lxc: config key %q is spelled %q on this liblxc version", e.Key, e.Suggestion)
+	case ConfigErrorDeprecated:
+		return fmt.Sprintf("lxc: config key %q still works but is deprecated in favor of %q on this liblxc version", e.Key, e.Suggestion)
+	default:
+		return fmt.Sprintf("lxc: config key %q is not supported by this liblxc version", e.Key)
+	}
+}
+
+// ValidateConfig checks items against the linked liblxc, reporting
+// unknown keys, keys that are only recognized under a different spelling
+// on this version, and keys that work but are deprecated in favor of a
+// renamed counterpart (see the key compatibility table in
+// config_compat.go). It lets callers pre-flight a container config before
+// ever calling LoadConfigFile.
+func ValidateConfig(items map[string]string) []ConfigError {
+	var errs []ConfigError
+
+	for key := range items {
+		if IsSupportedConfigItem(key) {
+			if renamed := NormalizeConfigKey(key); renamed != key {
+				errs = append(errs, ConfigError{Key: key, Kind: ConfigErrorDeprecated, Suggestion: renamed})
+			}
+			continue
+		}
+
+		if renamed := NormalizeConfigKey(key); renamed != key && IsSupportedConfigItem(renamed) {
+			errs = append(errs, ConfigError{Key: key, Kind: ConfigErrorRenamed, Suggestion: renamed})
+			continue
+		}
+
+		errs = append(errs, ConfigError{Key: key, Kind: ConfigErrorUnknown})
+	}
+
+	return errs
+}
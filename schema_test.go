@@ -0,0 +1,68 @@
+// Copyright © 2013, 2014, The Go-LXC Authors. All rights reserved.
+// Use of this source code is governed by a LGPLv2.1
+// license that can be found in the LICENSE file.
+
+// +build linux,cgo
+
+package lxc
+
+import "testing"
+
+func TestValidateConfigFlagsUnknownKey(t *testing.T) {
+	errs := ValidateConfig(map[string]string{"lxc.this.key.does.not.exist": "x"})
+
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one ConfigError, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Kind != ConfigErrorUnknown {
+		t.Fatalf("expected ConfigErrorUnknown, got %v", errs[0].Kind)
+	}
+	if errs[0].Error() == "" {
+		t.Fatalf("expected a non-empty error message")
+	}
+}
+
+// TestValidateConfigAcceptsSupportedKeys checks that every key
+// SupportedConfigItems reports as recognized is never flagged as unknown
+// or renamed by ValidateConfig. A key can still come back as
+// ConfigErrorDeprecated: SupportedConfigItems reports the old spelling of
+// a renamed key whenever the linked liblxc still recognizes it, and the
+// old spelling is, by definition, deprecated once a new one exists.
+func TestValidateConfigAcceptsSupportedKeys(t *testing.T) {
+	for _, key := range SupportedConfigItems() {
+		for _, err := range ValidateConfig(map[string]string{key: "x"}) {
+			if err.Kind == ConfigErrorUnknown || err.Kind == ConfigErrorRenamed {
+				t.Errorf("ValidateConfig flagged %q as %v, but SupportedConfigItems just reported it as supported", key, err.Kind)
+			}
+		}
+	}
+}
+
+// TestValidateConfigFlagsDeprecatedKey covers the gap where a key that
+// still works under its old spelling (liblxc's own compat aliasing) was
+// silently accepted instead of being reported as deprecated in favor of
+// its renamed counterpart.
+func TestValidateConfigFlagsDeprecatedKey(t *testing.T) {
+	for old, new := range configKeyRenames {
+		if !IsSupportedConfigItem(old) {
+			// Not supported under the old spelling on this liblxc version;
+			// nothing to report as deprecated, covered by the renamed case
+			// instead.
+			continue
+		}
+
+		errs := ValidateConfig(map[string]string{old: "x"})
+		if len(errs) != 1 {
+			t.Fatalf("ValidateConfig(%q): expected exactly one ConfigError, got %d: %v", old, len(errs), errs)
+		}
+		if errs[0].Kind != ConfigErrorDeprecated {
+			t.Fatalf("ValidateConfig(%q): expected ConfigErrorDeprecated, got %v", old, errs[0].Kind)
+		}
+		if errs[0].Suggestion != new {
+			t.Fatalf("ValidateConfig(%q): expected suggestion %q, got %q", old, new, errs[0].Suggestion)
+		}
+		return
+	}
+
+	t.Skip("no renamed config key is supported under its old spelling on this liblxc version")
+}